@@ -1,6 +1,7 @@
 package whatsapp
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -13,6 +14,11 @@ import (
 )
 
 func (wac *Conn) keepAlive(ws *websocketWrapper, minIntervalMs int, maxIntervalMs int) {
+	strategy := wac.KeepaliveStrategy
+	if strategy == nil {
+		strategy = NewTextFrameKeepalive(minIntervalMs, maxIntervalMs)
+	}
+
 	wac.log.Debugfln("Websocket keepalive loop starting %p", ws)
 	defer func() {
 		wac.log.Debugfln("Websocket keepalive loop exiting %p", ws)
@@ -23,26 +29,32 @@ func (wac *Conn) keepAlive(ws *websocketWrapper, minIntervalMs int, maxIntervalM
 		if ws.pingInKeepalive > 0 {
 			go wac.keepAliveAdminTest(ws)
 		}
-		err := wac.sendKeepAlive(ws)
+		sentAt := time.Now()
+		err := strategy.Ping(wac, ws)
 		if err != nil {
 			ws.keepAliveErrorCount += 1
+			wac.recordKeepAliveFailure(ws, err)
 			wac.log.Errorfln("Websocket keepalive for %p failed (error #%d): %v", ws, ws.keepAliveErrorCount, err)
 			if errors.Is(err, ErrConnectionTimeout) {
 				if ws.keepAliveErrorCount > 4 {
-					go wac.handle(ErrWebsocketKeepaliveFailed)
+					wac.autoReconnect(ws)
 					return
 				}
 				continue
 			} else if errors.Is(err, websocket.ErrCloseSent) {
 				return
 			}
-		} else if ws.keepAliveErrorCount > 0 {
-			wac.log.Debugfln("Websocket keepalive for %p is working again after %d errors", ws, ws.keepAliveErrorCount)
-			ws.keepAliveErrorCount = 0
+		} else {
+			wac.recordKeepAliveSuccess(ws, time.Since(sentAt))
+			if ws.keepAliveErrorCount > 0 {
+				wac.log.Debugfln("Websocket keepalive for %p is working again after %d errors", ws, ws.keepAliveErrorCount)
+				ws.keepAliveErrorCount = 0
+			}
 		}
-		interval := rand.Intn(maxIntervalMs-minIntervalMs) + minIntervalMs
+		min, max := strategy.Interval()
+		interval := min + time.Duration(rand.Int63n(int64(max-min)+1))
 		select {
-		case <-time.After(time.Duration(interval) * time.Millisecond):
+		case <-time.After(interval):
 		case <-ws.keepAliveShortCircuit:
 		case <-ws.ctx.Done():
 			return
@@ -56,6 +68,7 @@ func (wac *Conn) keepAliveAdminTest(ws *websocketWrapper) {
 		return
 	}
 	err := wac.AdminTest()
+	wac.recordAdminTestResult(err)
 	if err != nil {
 		wac.log.Warnln("Keepalive admin test failed:", err)
 		if errors.Is(err, ErrPingFalse) {
@@ -65,6 +78,7 @@ func (wac *Conn) keepAliveAdminTest(ws *websocketWrapper) {
 		wac.ws.pingInKeepalive--
 		if wac.ws.pingInKeepalive <= 0 {
 			wac.log.Infoln("Keepalive admin test successful, not pinging anymore")
+			wac.emitHealthEvent(HealthEventPingSuppressed, nil)
 		} else {
 			wac.log.Infofln("Keepalive admin test successful, stopping pings after %d more successes", wac.ws.pingInKeepalive)
 		}
@@ -105,6 +119,25 @@ func (wac *Conn) AdminTest() error {
 	return wac.AdminTestWithSuppress(false)
 }
 
+// AdminTestContext behaves like AdminTest, but also returns early with
+// ctx.Err() if ctx is done before the server responds. Unlike a plain
+// msgTimeout expiry, a cancelled ctx does not bump keepAliveErrorCount,
+// since it reflects the caller losing interest rather than the connection
+// being unhealthy.
+func (wac *Conn) AdminTestContext(ctx context.Context) error {
+	if !wac.connected {
+		return ErrNotConnected
+	}
+	if !wac.loggedIn {
+		return ErrNotLoggedIn
+	}
+	err := wac.sendAdminTest(ctx)
+	if wac.AdminTestHook != nil {
+		wac.AdminTestHook(err)
+	}
+	return err
+}
+
 func (wac *Conn) AdminTestWithSuppress(suppressHook bool) error {
 	if !wac.connected {
 		return ErrNotConnected
@@ -112,7 +145,7 @@ func (wac *Conn) AdminTestWithSuppress(suppressHook bool) error {
 	if !wac.loggedIn {
 		return ErrNotLoggedIn
 	}
-	err := wac.sendAdminTest()
+	err := wac.sendAdminTest(context.Background())
 	if !suppressHook && wac.AdminTestHook != nil {
 		wac.AdminTestHook(err)
 	}
@@ -164,7 +197,7 @@ func (atw *adminTestWait) handleResp(resp string) error {
 	return fmt.Errorf("unexpected ping response: %s", resp)
 }
 
-func (atw *adminTestWait) Listen() <-chan error {
+func (atw *adminTestWait) Listen() chan error {
 	atw.Lock()
 	ch := make(chan error, 1)
 	if atw.done {
@@ -176,9 +209,23 @@ func (atw *adminTestWait) Listen() <-chan error {
 	return ch
 }
 
+// forget removes ch from the list of listeners woken up by wait, so a
+// cancelled caller doesn't leak a channel that will never be read from.
+func (atw *adminTestWait) forget(ch chan error) {
+	atw.Lock()
+	for i, c := range atw.output {
+		if c == ch {
+			atw.output = append(atw.output[:i], atw.output[i+1:]...)
+			break
+		}
+	}
+	atw.Unlock()
+}
+
 func (wac *Conn) CountTimeout() {
 	if wac.ws != nil {
 		wac.ws.countTimeout()
+		wac.recordTimeout()
 		if wac.CountTimeoutHook != nil {
 			wac.CountTimeoutHook(wac.ws.keepAliveErrorCount)
 		}
@@ -187,7 +234,7 @@ func (wac *Conn) CountTimeout() {
 
 const adminTest = `["admin","test"]`
 
-func (wac *Conn) sendAdminTest() error {
+func (wac *Conn) sendAdminTest(ctx context.Context) error {
 	wac.atwLock.Lock()
 	if wac.atw == nil || wac.atw.done {
 		wac.atw = newAdminTestWait()
@@ -201,12 +248,14 @@ func (wac *Conn) sendAdminTest() error {
 	bytes := []byte(fmt.Sprintf("%s,%s", messageTag, adminTest))
 	err := wac.ws.write(websocket.TextMessage, bytes)
 	if err != nil {
+		wac.listener.removeWaiter(messageTag)
 		return fmt.Errorf("error sending admin test: %w", err)
 	}
 	wac.msgCount++
 
+	ch := atw.Listen()
 	select {
-	case err = <-atw.Listen():
+	case err = <-ch:
 		return err
 	case <-time.After(wac.msgTimeout):
 		wac.CountTimeout()
@@ -214,5 +263,12 @@ func (wac *Conn) sendAdminTest() error {
 			return ErrWebsocketKeepaliveFailed
 		}
 		return ErrConnectionTimeout
+	case <-ctx.Done():
+		atw.forget(ch)
+		wac.listener.removeWaiter(messageTag)
+		return ctx.Err()
+	case <-wac.ws.ctx.Done():
+		atw.forget(ch)
+		return nil
 	}
 }
@@ -0,0 +1,137 @@
+package whatsapp
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// KeepaliveStrategy abstracts the mechanics of keeping the websocket alive,
+// so deployments that need something other than the default "?,," text
+// frame can plug in their own without forking the library.
+type KeepaliveStrategy interface {
+	// Ping sends a single keepalive probe on ws and blocks until the
+	// server has responded, the probe times out, or ws.ctx is cancelled.
+	Ping(wac *Conn, ws *websocketWrapper) error
+
+	// Interval returns the min/max bounds used to randomize the delay
+	// between keepalive probes.
+	Interval() (min, max time.Duration)
+}
+
+// textFrameKeepalive is the original keepalive strategy: it sends the
+// literal "?,," text frame and waits for the server to echo back the
+// current timestamp. The admin-test pings are not part of the strategy;
+// the keepAlive loop starts them for every strategy while pingInKeepalive
+// is positive.
+type textFrameKeepalive struct {
+	minIntervalMs int
+	maxIntervalMs int
+}
+
+// NewTextFrameKeepalive returns the default KeepaliveStrategy, which sends
+// the historical "?,," text frame.
+func NewTextFrameKeepalive(minIntervalMs, maxIntervalMs int) KeepaliveStrategy {
+	return &textFrameKeepalive{minIntervalMs: minIntervalMs, maxIntervalMs: maxIntervalMs}
+}
+
+func (k *textFrameKeepalive) Ping(wac *Conn, ws *websocketWrapper) error {
+	return wac.sendKeepAlive(ws)
+}
+
+func (k *textFrameKeepalive) Interval() (time.Duration, time.Duration) {
+	return time.Duration(k.minIntervalMs) * time.Millisecond, time.Duration(k.maxIntervalMs) * time.Millisecond
+}
+
+// controlFrameKeepalive uses gorilla/websocket's native ping/pong control
+// frames instead of a text frame, for deployments behind proxies that strip
+// text-frame heartbeats but pass control frames through untouched.
+type controlFrameKeepalive struct {
+	minInterval time.Duration
+	maxInterval time.Duration
+	pongTimeout time.Duration
+
+	handlerMu    sync.Mutex
+	registeredWS *websocketWrapper
+	pong         chan struct{}
+	pendingTag   string
+	tagSeq       uint64
+}
+
+// NewControlFrameKeepalive returns a KeepaliveStrategy built on
+// websocket.Conn's ping/pong control frames.
+func NewControlFrameKeepalive(minInterval, maxInterval, pongTimeout time.Duration) KeepaliveStrategy {
+	return &controlFrameKeepalive{minInterval: minInterval, maxInterval: maxInterval, pongTimeout: pongTimeout}
+}
+
+// ensureHandler registers the pong handler with ws.conn the first time it
+// sees ws. gorilla/websocket doesn't document SetPongHandler as safe to call
+// while a read loop is in flight, so this only runs once per websocket
+// rather than on every Ping. The handler itself only signals whichever round
+// is currently pending and only if the pong's tag matches it, so a pong that
+// arrives after its own round already timed out can't be mistaken for an
+// ack of the next round's ping.
+func (k *controlFrameKeepalive) ensureHandler(ws *websocketWrapper) {
+	k.handlerMu.Lock()
+	defer k.handlerMu.Unlock()
+
+	if k.registeredWS == ws {
+		return
+	}
+
+	ws.conn.SetPongHandler(func(appData string) error {
+		k.handlerMu.Lock()
+		pong, tag := k.pong, k.pendingTag
+		k.handlerMu.Unlock()
+
+		if pong != nil && appData == tag {
+			select {
+			case pong <- struct{}{}:
+			default:
+			}
+		}
+		return ws.conn.SetReadDeadline(time.Now().Add(k.pongTimeout))
+	})
+	k.registeredWS = ws
+}
+
+// beginRound starts a fresh keepalive round: a new channel so a late pong
+// belonging to a previous, already-timed-out round can't be read as this
+// round's ack, and a new tag so the pong handler can tell which round's
+// pong just arrived.
+func (k *controlFrameKeepalive) beginRound() (pong chan struct{}, tag string) {
+	k.handlerMu.Lock()
+	defer k.handlerMu.Unlock()
+
+	k.tagSeq++
+	tag = strconv.FormatUint(k.tagSeq, 10)
+	pong = make(chan struct{}, 1)
+	k.pong, k.pendingTag = pong, tag
+	return pong, tag
+}
+
+func (k *controlFrameKeepalive) Ping(wac *Conn, ws *websocketWrapper) error {
+	k.ensureHandler(ws)
+	pong, tag := k.beginRound()
+
+	deadline := time.Now().Add(k.pongTimeout)
+	if err := ws.conn.WriteControl(websocket.PingMessage, []byte(tag), deadline); err != nil {
+		return fmt.Errorf("error sending keepalive ping: %w", err)
+	}
+
+	select {
+	case <-pong:
+		return nil
+	case <-time.After(k.pongTimeout):
+		return ErrConnectionTimeout
+	case <-ws.ctx.Done():
+		return nil
+	}
+}
+
+func (k *controlFrameKeepalive) Interval() (time.Duration, time.Duration) {
+	return k.minInterval, k.maxInterval
+}
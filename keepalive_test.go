@@ -0,0 +1,41 @@
+package whatsapp
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAdminTestWaitForgetDropsListener(t *testing.T) {
+	atw := &adminTestWait{output: make([]chan error, 0)}
+
+	ch := atw.Listen()
+	atw.forget(ch)
+
+	atw.result = errors.New("boom")
+	atw.Lock()
+	for _, c := range atw.output {
+		c <- atw.result
+	}
+	atw.Unlock()
+
+	select {
+	case <-ch:
+		t.Fatal("forgotten listener should never receive a result")
+	default:
+	}
+}
+
+func TestAdminTestWaitListenReceivesAlreadyDoneResult(t *testing.T) {
+	wantErr := errors.New("boom")
+	atw := &adminTestWait{done: true, result: wantErr, output: make([]chan error, 0)}
+
+	ch := atw.Listen()
+	select {
+	case err := <-ch:
+		if !errors.Is(err, wantErr) {
+			t.Fatalf("expected %v, got %v", wantErr, err)
+		}
+	default:
+		t.Fatal("Listen on an already-done wait should deliver immediately")
+	}
+}
@@ -0,0 +1,157 @@
+package whatsapp
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// ReconnectConfig controls the automatic reconnection behaviour that kicks
+// in when the keepalive loop gives up on the current websocket. The zero
+// value disables automatic reconnection, preserving the previous
+// fire-ErrWebsocketKeepaliveFailed-and-exit behaviour.
+type ReconnectConfig struct {
+	Enabled bool
+
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	// MaxAttempts is the number of redial attempts before giving up and
+	// falling back to wac.handle(ErrWebsocketKeepaliveFailed). Zero means
+	// retry forever.
+	MaxAttempts int
+
+	// Jitter is the fraction (0..1) of each backoff step that is
+	// randomized, to avoid reconnect storms across many clients.
+	Jitter float64
+
+	OnReconnect       func(attempt int)
+	OnReconnectFailed func(attempt int, err error)
+}
+
+// DefaultReconnectConfig returns a ReconnectConfig with sane defaults,
+// matching the backoff used by most gorilla/websocket-based clients.
+func DefaultReconnectConfig() ReconnectConfig {
+	return ReconnectConfig{
+		Enabled:     true,
+		MinBackoff:  1 * time.Second,
+		MaxBackoff:  5 * time.Minute,
+		MaxAttempts: 0,
+		Jitter:      0.2,
+	}
+}
+
+func (wac *Conn) reconnectBackoff(attempt int) time.Duration {
+	return reconnectBackoff(wac.ReconnectConfig, attempt)
+}
+
+// reconnectBackoff computes the exponential-backoff delay for the given
+// zero-based attempt number, clamped to cfg.MaxBackoff and randomized by
+// cfg.Jitter. Split out from the Conn method so the math can be unit
+// tested without a live connection.
+func reconnectBackoff(cfg ReconnectConfig, attempt int) time.Duration {
+	backoff := float64(cfg.MinBackoff) * math.Pow(2, float64(attempt))
+	if max := float64(cfg.MaxBackoff); cfg.MaxBackoff > 0 && backoff > max {
+		backoff = max
+	}
+	if cfg.Jitter > 0 {
+		delta := backoff * cfg.Jitter
+		backoff += delta*rand.Float64()*2 - delta
+	}
+	return time.Duration(backoff)
+}
+
+// autoReconnect takes over for the keepalive loop once it has decided the
+// websocket is dead. It redials and restores the session using the stored
+// auth blob, backing off between attempts. ws.ctx is checked between
+// attempts (during the backoff sleep) and again right after a successful
+// restoreSession, so a Disconnect that lands while a redial is already in
+// flight still results in a clean shutdown instead of a reconnect. If
+// reconnection is disabled or all attempts are exhausted, it falls back to
+// the previous behaviour of handing the error to wac.handle.
+func (wac *Conn) autoReconnect(ws *websocketWrapper) {
+	cfg := wac.ReconnectConfig
+	if !cfg.Enabled {
+		go wac.handle(ErrWebsocketKeepaliveFailed)
+		return
+	}
+
+	for attempt := 1; cfg.MaxAttempts == 0 || attempt <= cfg.MaxAttempts; attempt++ {
+		select {
+		case <-time.After(wac.reconnectBackoff(attempt - 1)):
+		case <-ws.ctx.Done():
+			return
+		}
+
+		wac.log.Infofln("Reconnecting websocket %p (attempt %d)", ws, attempt)
+		err := wac.restoreSession()
+		if err == nil {
+			select {
+			case <-ws.ctx.Done():
+				// Disconnect was requested while restoreSession was
+				// in flight; tear the connection it just stood back
+				// up down instead of reporting success.
+				wac.log.Debugfln("Reconnect for %p succeeded after Disconnect was requested; disconnecting again", ws)
+				wac.Disconnect()
+				return
+			default:
+			}
+
+			wac.log.Infofln("Reconnected websocket %p after %d attempt(s)", ws, attempt)
+			wac.recordReconnect()
+			if cfg.OnReconnect != nil {
+				cfg.OnReconnect(attempt)
+			}
+			return
+		}
+
+		wac.log.Warnfln("Reconnect attempt %d for %p failed: %v", attempt, ws, err)
+		if cfg.OnReconnectFailed != nil {
+			cfg.OnReconnectFailed(attempt, err)
+		}
+	}
+
+	wac.log.Errorfln("Giving up reconnecting websocket %p after %d attempts", ws, cfg.MaxAttempts)
+	go wac.handle(ErrWebsocketKeepaliveFailed)
+}
+
+// SetSession records the auth blob that autoReconnect should restore the
+// next time it has to redial. The original Login/RestoreWithSession call
+// path must call this (in addition to whatever it already does with the
+// returned Session) for reconnection to work on the very first disconnect;
+// restoreSession only ever repopulates it itself on later reconnects.
+func (wac *Conn) SetSession(session Session) {
+	wac.sessionLock.Lock()
+	defer wac.sessionLock.Unlock()
+	wac.session = &session
+}
+
+func (wac *Conn) currentSession() (Session, bool) {
+	wac.sessionLock.RLock()
+	defer wac.sessionLock.RUnlock()
+	if wac.session == nil {
+		return Session{}, false
+	}
+	return *wac.session, true
+}
+
+// restoreSession redials the WhatsApp websocket and re-establishes the
+// session from the last known-good auth blob, so a caller observing
+// ReconnectConfig.OnReconnect never has to rebuild the Conn itself.
+func (wac *Conn) restoreSession() error {
+	session, ok := wac.currentSession()
+	if !ok {
+		return ErrInvalidSession
+	}
+
+	if err := wac.Connect(); err != nil {
+		return err
+	}
+
+	restored, err := wac.RestoreWithSession(session)
+	if err != nil {
+		return err
+	}
+	wac.SetSession(restored)
+	return nil
+}
@@ -0,0 +1,52 @@
+package whatsapp
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReconnectBackoffClampsToMax(t *testing.T) {
+	cfg := ReconnectConfig{
+		MinBackoff: 1 * time.Second,
+		MaxBackoff: 10 * time.Second,
+	}
+
+	for attempt := 0; attempt < 10; attempt++ {
+		if d := reconnectBackoff(cfg, attempt); d > cfg.MaxBackoff {
+			t.Fatalf("attempt %d: backoff %v exceeds MaxBackoff %v", attempt, d, cfg.MaxBackoff)
+		}
+	}
+}
+
+func TestReconnectBackoffGrowsExponentially(t *testing.T) {
+	cfg := ReconnectConfig{
+		MinBackoff: 1 * time.Second,
+		MaxBackoff: time.Hour,
+	}
+
+	prev := time.Duration(0)
+	for attempt := 0; attempt < 5; attempt++ {
+		d := reconnectBackoff(cfg, attempt)
+		if d <= prev {
+			t.Fatalf("attempt %d: backoff %v did not grow past previous %v", attempt, d, prev)
+		}
+		prev = d
+	}
+}
+
+func TestReconnectBackoffJitterStaysWithinBounds(t *testing.T) {
+	cfg := ReconnectConfig{
+		MinBackoff: 10 * time.Second,
+		MaxBackoff: 10 * time.Second,
+		Jitter:     0.5,
+	}
+
+	lo := cfg.MinBackoff - time.Duration(float64(cfg.MinBackoff)*cfg.Jitter) - time.Millisecond
+	hi := cfg.MinBackoff + time.Duration(float64(cfg.MinBackoff)*cfg.Jitter) + time.Millisecond
+	for i := 0; i < 100; i++ {
+		d := reconnectBackoff(cfg, 0)
+		if d < lo || d > hi {
+			t.Fatalf("jittered backoff %v outside expected range [%v, %v]", d, lo, hi)
+		}
+	}
+}
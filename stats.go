@@ -0,0 +1,155 @@
+package whatsapp
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// HealthEventType identifies the kind of state transition a HealthEvent
+// reports.
+type HealthEventType int
+
+const (
+	// HealthEventKeepaliveDegraded fires the first time a keepalive
+	// probe fails after the connection was healthy.
+	HealthEventKeepaliveDegraded HealthEventType = iota
+	// HealthEventKeepaliveRecovered fires when a keepalive probe
+	// succeeds again after one or more failures.
+	HealthEventKeepaliveRecovered
+	// HealthEventPingSuppressed fires when the admin-test ping is
+	// stopped after keepAliveAdminTest has confirmed the connection.
+	HealthEventPingSuppressed
+	// HealthEventTimeout fires every time CountTimeout is invoked.
+	HealthEventTimeout
+	// HealthEventReconnected fires when autoReconnect successfully
+	// redials the websocket.
+	HealthEventReconnected
+)
+
+// HealthEvent describes a single state transition observed by the
+// keepalive/reconnect machinery, for callers that want to drive alerting
+// off the same signals the library already computes internally.
+type HealthEvent struct {
+	Type      HealthEventType
+	Timestamp time.Time
+	Err       error
+}
+
+// ConnStats holds atomically-updated counters and gauges describing the
+// health of a Conn's websocket over time. All fields are safe for
+// concurrent use; read them with the Snapshot method rather than directly.
+type ConnStats struct {
+	keepAlivesSent      int64
+	keepAliveFailures   int64
+	keepAliveRTTTotalMs int64
+	adminTestSuccesses  int64
+	adminTestFailures   int64
+	timeouts            int64
+	reconnects          int64
+}
+
+// ConnStatsSnapshot is a point-in-time copy of ConnStats, safe to read
+// without further synchronization.
+type ConnStatsSnapshot struct {
+	KeepAlivesSent     int64
+	KeepAliveFailures  int64
+	KeepAliveAvgRTTMs  int64
+	AdminTestSuccesses int64
+	AdminTestFailures  int64
+	Timeouts           int64
+	Reconnects         int64
+}
+
+// Snapshot returns a copy of the stats. Each counter is loaded atomically on
+// its own, not together with the others, so a snapshot taken while counters
+// are being updated may mix values from before and after an update. It is safe
+// to call on a nil *ConnStats (returning a zero ConnStatsSnapshot), since
+// Conn.Stats stays nil until the first keepalive tick populates it and
+// callers may poll it before that, e.g. from an HTTP handler right after
+// construction.
+func (s *ConnStats) Snapshot() ConnStatsSnapshot {
+	if s == nil {
+		return ConnStatsSnapshot{}
+	}
+	sent := atomic.LoadInt64(&s.keepAlivesSent)
+	failures := atomic.LoadInt64(&s.keepAliveFailures)
+	var avgRTT int64
+	if successes := sent - failures; successes > 0 {
+		avgRTT = atomic.LoadInt64(&s.keepAliveRTTTotalMs) / successes
+	}
+	return ConnStatsSnapshot{
+		KeepAlivesSent:     sent,
+		KeepAliveFailures:  failures,
+		KeepAliveAvgRTTMs:  avgRTT,
+		AdminTestSuccesses: atomic.LoadInt64(&s.adminTestSuccesses),
+		AdminTestFailures:  atomic.LoadInt64(&s.adminTestFailures),
+		Timeouts:           atomic.LoadInt64(&s.timeouts),
+		Reconnects:         atomic.LoadInt64(&s.reconnects),
+	}
+}
+
+func (wac *Conn) stats() *ConnStats {
+	wac.statsOnce.Do(func() {
+		wac.Stats = &ConnStats{}
+	})
+	return wac.Stats
+}
+
+// StatsSnapshot returns a ConnStats snapshot, initializing wac.Stats on
+// first use if no keepalive tick has run yet. Prefer this over reading
+// wac.Stats directly (which is nil until then) when polling from outside
+// the keepalive loop, e.g. a Prometheus-exporting HTTP handler.
+func (wac *Conn) StatsSnapshot() ConnStatsSnapshot {
+	return wac.stats().Snapshot()
+}
+
+func (wac *Conn) emitHealthEvent(t HealthEventType, err error) {
+	if wac.HealthEventHook != nil {
+		wac.HealthEventHook(HealthEvent{Type: t, Timestamp: time.Now(), Err: err})
+	}
+}
+
+// recordKeepAliveSuccess and recordKeepAliveFailure are called from the
+// generic keepAlive loop around strategy.Ping, not from any one
+// KeepaliveStrategy implementation, so every strategy gets the same
+// ConnStats/HealthEvent coverage for free.
+func (wac *Conn) recordKeepAliveSuccess(ws *websocketWrapper, rtt time.Duration) {
+	stats := wac.stats()
+	atomic.AddInt64(&stats.keepAlivesSent, 1)
+	atomic.AddInt64(&stats.keepAliveRTTTotalMs, rtt.Milliseconds())
+	if ws.keepAliveErrorCount > 0 {
+		wac.emitHealthEvent(HealthEventKeepaliveRecovered, nil)
+	}
+}
+
+// recordKeepAliveFailure must be called after ws.keepAliveErrorCount has
+// already been incremented for this failure, so the consecutive-failure
+// count (not the lifetime-cumulative one) decides when the degraded event
+// fires — it needs to be able to fire again after a later recovery.
+func (wac *Conn) recordKeepAliveFailure(ws *websocketWrapper, err error) {
+	stats := wac.stats()
+	atomic.AddInt64(&stats.keepAlivesSent, 1)
+	atomic.AddInt64(&stats.keepAliveFailures, 1)
+	if ws.keepAliveErrorCount == 1 {
+		wac.emitHealthEvent(HealthEventKeepaliveDegraded, err)
+	}
+}
+
+func (wac *Conn) recordAdminTestResult(err error) {
+	stats := wac.stats()
+	if err != nil {
+		atomic.AddInt64(&stats.adminTestFailures, 1)
+	} else {
+		atomic.AddInt64(&stats.adminTestSuccesses, 1)
+	}
+}
+
+func (wac *Conn) recordTimeout() {
+	atomic.AddInt64(&wac.stats().timeouts, 1)
+	wac.emitHealthEvent(HealthEventTimeout, nil)
+}
+
+func (wac *Conn) recordReconnect() {
+	atomic.AddInt64(&wac.stats().reconnects, 1)
+	wac.emitHealthEvent(HealthEventReconnected, nil)
+}
@@ -0,0 +1,35 @@
+package whatsapp
+
+import "testing"
+
+func TestConnStatsSnapshotAveragesOverSuccessesOnly(t *testing.T) {
+	stats := &ConnStats{
+		keepAlivesSent:      5,
+		keepAliveFailures:   2,
+		keepAliveRTTTotalMs: 300, // only the 3 successful pings contributed RTT
+	}
+
+	snap := stats.Snapshot()
+	if snap.KeepAliveAvgRTTMs != 100 {
+		t.Fatalf("expected average RTT of 100ms over 3 successes, got %d", snap.KeepAliveAvgRTTMs)
+	}
+}
+
+func TestConnStatsSnapshotAllFailuresYieldsZeroAverage(t *testing.T) {
+	stats := &ConnStats{
+		keepAlivesSent:    3,
+		keepAliveFailures: 3,
+	}
+
+	snap := stats.Snapshot()
+	if snap.KeepAliveAvgRTTMs != 0 {
+		t.Fatalf("expected zero average RTT with no successes, got %d", snap.KeepAliveAvgRTTMs)
+	}
+}
+
+func TestConnStatsSnapshotOnNilStatsDoesNotPanic(t *testing.T) {
+	var stats *ConnStats
+	if snap := stats.Snapshot(); snap != (ConnStatsSnapshot{}) {
+		t.Fatalf("expected zero-value snapshot from nil *ConnStats, got %+v", snap)
+	}
+}